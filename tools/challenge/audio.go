@@ -0,0 +1,137 @@
+package challenge
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+const (
+	audioSampleRate   = 8000
+	audioDigitSeconds = 0.3
+	audioGapSeconds   = 0.15
+)
+
+// audioDigitFreq assigns each digit a distinct tone frequency (Hz), the way
+// DTMF assigns each keypad digit a pair of tones. Digits are rendered as
+// these tones only; no speech is synthesized or played back.
+var audioDigitFreq = [10]float64{
+	350, 400, 450, 500, 550, 600, 650, 700, 750, 800,
+}
+
+func init() {
+	register("audio", func() Challenge { return &audioChallenge{} })
+}
+
+// audioChallenge is the audio counterpart of digitChallenge: the same kind
+// of random digit string, rendered as a WAV clip for visually impaired
+// users instead of a PNG.
+type audioChallenge struct {
+	IDValue string `json:"id"`
+	Digits  string `json:"digits"`
+}
+
+// NewAudio returns a Challenge made of n random digits, rendered as audio.
+func NewAudio(n int) Challenge {
+	digits := make([]byte, n)
+	for i := range digits {
+		digits[i] = byte('0' + secureIntn(10))
+	}
+	return &audioChallenge{IDValue: newID(), Digits: string(digits)}
+}
+
+func (c *audioChallenge) ID() string { return c.IDValue }
+
+func (c *audioChallenge) Render(w io.Writer, mimeType string) error {
+	switch mimeType {
+	case "audio/wav":
+		return writeWAV(w, renderDigitTones(c.Digits))
+	default:
+		return ErrUnsupportedMimeType
+	}
+}
+
+func (c *audioChallenge) Verify(input string) bool {
+	return input == c.Digits
+}
+
+func (c *audioChallenge) kind() string { return "audio" }
+
+// renderDigitTones synthesizes one tone per digit, separated by silence,
+// as 16-bit PCM samples.
+func renderDigitTones(digits string) []int16 {
+	digitSamples := int(audioDigitSeconds * audioSampleRate)
+	gapSamples := int(audioGapSeconds * audioSampleRate)
+	samples := make([]int16, 0, len(digits)*(digitSamples+gapSamples))
+
+	for _, d := range digits {
+		freq := audioDigitFreq[d-'0']
+		for i := 0; i < digitSamples; i++ {
+			t := float64(i) / audioSampleRate
+			// Linear fade in/out avoids audible clicks at tone boundaries.
+			fade := fadeEnvelope(i, digitSamples)
+			v := math.Sin(2*math.Pi*freq*t) * fade
+			samples = append(samples, int16(v*math.MaxInt16*0.8))
+		}
+		samples = append(samples, make([]int16, gapSamples)...)
+	}
+	return samples
+}
+
+func fadeEnvelope(i, n int) float64 {
+	const fadeSamples = audioSampleRate / 100 // 10ms
+	switch {
+	case i < fadeSamples:
+		return float64(i) / fadeSamples
+	case i >= n-fadeSamples:
+		return float64(n-i) / fadeSamples
+	default:
+		return 1
+	}
+}
+
+// writeWAV writes samples as a mono 16-bit PCM WAV file.
+func writeWAV(w io.Writer, samples []int16) error {
+	const (
+		bitsPerSample = 16
+		numChannels   = 1
+	)
+	dataSize := len(samples) * 2
+	byteRate := audioSampleRate * numChannels * bitsPerSample / 8
+	blockAlign := numChannels * bitsPerSample / 8
+
+	header := struct {
+		ChunkID       [4]byte
+		ChunkSize     uint32
+		Format        [4]byte
+		Subchunk1ID   [4]byte
+		Subchunk1Size uint32
+		AudioFormat   uint16
+		NumChannels   uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+		Subchunk2ID   [4]byte
+		Subchunk2Size uint32
+	}{
+		ChunkID:       [4]byte{'R', 'I', 'F', 'F'},
+		ChunkSize:     uint32(36 + dataSize),
+		Format:        [4]byte{'W', 'A', 'V', 'E'},
+		Subchunk1ID:   [4]byte{'f', 'm', 't', ' '},
+		Subchunk1Size: 16,
+		AudioFormat:   1, // PCM
+		NumChannels:   numChannels,
+		SampleRate:    audioSampleRate,
+		ByteRate:      uint32(byteRate),
+		BlockAlign:    uint16(blockAlign),
+		BitsPerSample: bitsPerSample,
+		Subchunk2ID:   [4]byte{'d', 'a', 't', 'a'},
+		Subchunk2Size: uint32(dataSize),
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, samples)
+}
@@ -0,0 +1,92 @@
+// Package challenge implements the captcha challenges gorobbs can hand out:
+// numeric-digit and distorted-text images, digit audio (one tone per digit,
+// not speech), and
+// math-word problems. Each one implements the Challenge interface so HTTP
+// handlers can content-negotiate a rendering and store.Store can persist
+// them without caring which kind it got.
+package challenge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Challenge is a single captcha puzzle: something rendered to the user and
+// checked against their response.
+type Challenge interface {
+	// ID uniquely identifies this challenge instance, for looking it back
+	// up in a store.Store to verify an answer.
+	ID() string
+
+	// Render writes the challenge encoded as mimeType. Each implementation
+	// supports a subset of "image/png", "audio/wav" and "text/plain";
+	// ErrUnsupportedMimeType is returned for anything else.
+	Render(w io.Writer, mimeType string) error
+
+	// Verify reports whether input solves the challenge.
+	Verify(input string) bool
+}
+
+// ErrUnsupportedMimeType is returned by Render when asked to produce a MIME
+// type the challenge doesn't know how to generate.
+var ErrUnsupportedMimeType = errors.New("challenge: unsupported mime type")
+
+// kinded is implemented by every Challenge in this package so Marshal and
+// Unmarshal can round-trip one through a store.Store, which only persists
+// strings. It isn't part of the public Challenge contract because callers
+// outside this package never need to name a kind themselves.
+type kinded interface {
+	Challenge
+	kind() string
+}
+
+// envelope is the JSON shape persisted in a store.Store in place of a bare
+// answer string.
+type envelope struct {
+	Kind string          `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+// registry maps a kind name to a zero-value constructor, populated by each
+// implementation's init func via register.
+var registry = map[string]func() Challenge{}
+
+func register(kind string, newChallenge func() Challenge) {
+	registry[kind] = newChallenge
+}
+
+// Marshal serializes c into the payload a store.Store should save for it.
+func Marshal(c Challenge) (string, error) {
+	k, ok := c.(kinded)
+	if !ok {
+		return "", fmt.Errorf("challenge: %T does not support serialization", c)
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	out, err := json.Marshal(envelope{Kind: k.kind(), Data: data})
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// Unmarshal restores a Challenge previously serialized with Marshal.
+func Unmarshal(payload string) (Challenge, error) {
+	var env envelope
+	if err := json.Unmarshal([]byte(payload), &env); err != nil {
+		return nil, err
+	}
+	newChallenge, ok := registry[env.Kind]
+	if !ok {
+		return nil, fmt.Errorf("challenge: unknown kind %q", env.Kind)
+	}
+	c := newChallenge()
+	if err := json.Unmarshal(env.Data, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
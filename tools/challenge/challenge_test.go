@@ -0,0 +1,143 @@
+package challenge
+
+import (
+	"bytes"
+	"image/png"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDigitChallenge(t *testing.T) {
+	c := NewDigit(6)
+	dc := c.(*digitChallenge)
+
+	if !c.Verify(dc.Digits) {
+		t.Fatal("Verify of the correct digits returned false")
+	}
+	if c.Verify("wrong!") {
+		t.Fatal("Verify of the wrong digits returned true")
+	}
+
+	var buf bytes.Buffer
+	if err := c.Render(&buf, "image/png"); err != nil {
+		t.Fatalf("Render image/png: %v", err)
+	}
+	if _, err := png.Decode(&buf); err != nil {
+		t.Fatalf("rendered image/png output doesn't decode as PNG: %v", err)
+	}
+
+	if err := c.Render(&buf, "text/plain"); err != ErrUnsupportedMimeType {
+		t.Fatalf("Render text/plain: got %v, want ErrUnsupportedMimeType (text/plain would leak the answer)", err)
+	}
+
+	roundTripAndVerify(t, c, dc.Digits)
+}
+
+func TestTextChallenge(t *testing.T) {
+	c := NewText(6)
+	tc := c.(*textChallenge)
+
+	if !c.Verify(tc.Text) {
+		t.Fatal("Verify of the correct text returned false")
+	}
+	if c.Verify("wrong!") {
+		t.Fatal("Verify of the wrong text returned true")
+	}
+
+	var buf bytes.Buffer
+	if err := c.Render(&buf, "image/png"); err != nil {
+		t.Fatalf("Render image/png: %v", err)
+	}
+	if _, err := png.Decode(&buf); err != nil {
+		t.Fatalf("rendered image/png output doesn't decode as PNG: %v", err)
+	}
+
+	if err := c.Render(&buf, "text/plain"); err != ErrUnsupportedMimeType {
+		t.Fatalf("Render text/plain: got %v, want ErrUnsupportedMimeType (text/plain would leak the answer)", err)
+	}
+
+	roundTripAndVerify(t, c, tc.Text)
+}
+
+func TestAudioChallenge(t *testing.T) {
+	c := NewAudio(4)
+	ac := c.(*audioChallenge)
+
+	if !c.Verify(ac.Digits) {
+		t.Fatal("Verify of the correct digits returned false")
+	}
+	if c.Verify("wrong!") {
+		t.Fatal("Verify of the wrong digits returned true")
+	}
+
+	var buf bytes.Buffer
+	if err := c.Render(&buf, "audio/wav"); err != nil {
+		t.Fatalf("Render audio/wav: %v", err)
+	}
+	if got := buf.String()[:4]; got != "RIFF" {
+		t.Fatalf("rendered audio/wav output doesn't start with a RIFF header, got %q", got)
+	}
+	if got := buf.String()[8:12]; got != "WAVE" {
+		t.Fatalf("rendered audio/wav output isn't WAVE format, got %q", got)
+	}
+
+	if err := c.Render(&buf, "text/plain"); err != ErrUnsupportedMimeType {
+		t.Fatalf("Render text/plain: got %v, want ErrUnsupportedMimeType (text/plain would leak the answer)", err)
+	}
+
+	roundTripAndVerify(t, c, ac.Digits)
+}
+
+func TestMathChallenge(t *testing.T) {
+	c := NewMath()
+	mc := c.(*mathChallenge)
+
+	answer := formatAnswer(mc.Answer)
+	if !c.Verify(answer) {
+		t.Fatalf("Verify(%q) returned false for the correct answer", answer)
+	}
+	if c.Verify(formatAnswer(mc.Answer + 100)) {
+		t.Fatal("Verify of the wrong answer returned true")
+	}
+
+	var buf bytes.Buffer
+	if err := c.Render(&buf, "text/plain"); err != nil {
+		t.Fatalf("Render text/plain: %v", err)
+	}
+	if got := buf.String(); got != mc.Prompt {
+		t.Fatalf("Render text/plain = %q, want the prompt %q", got, mc.Prompt)
+	}
+	if strings.Contains(buf.String(), answer) {
+		t.Fatalf("rendered prompt %q leaks the numeric answer %q", buf.String(), answer)
+	}
+
+	roundTripAndVerify(t, c, answer)
+}
+
+// roundTripAndVerify marshals c, unmarshals it back, and checks the
+// restored Challenge has the same ID and still verifies correctAnswer.
+func roundTripAndVerify(t *testing.T, c Challenge, correctAnswer string) {
+	t.Helper()
+
+	payload, err := Marshal(c)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	restored, err := Unmarshal(payload)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if restored.ID() != c.ID() {
+		t.Fatalf("restored ID = %q, want %q", restored.ID(), c.ID())
+	}
+	if !restored.Verify(correctAnswer) {
+		t.Fatalf("restored challenge did not verify the correct answer %q", correctAnswer)
+	}
+}
+
+func formatAnswer(n int) string {
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,41 @@
+package challenge
+
+import "io"
+
+func init() {
+	register("digit", func() Challenge { return &digitChallenge{} })
+}
+
+// digitChallenge is a straight numeric-digit image captcha: a string of
+// random digits rendered without distortion, the kind gorobbs has always
+// issued.
+type digitChallenge struct {
+	IDValue string `json:"id"`
+	Digits  string `json:"digits"`
+}
+
+// NewDigit returns a Challenge made of n random digits.
+func NewDigit(n int) Challenge {
+	digits := make([]byte, n)
+	for i := range digits {
+		digits[i] = byte('0' + secureIntn(10))
+	}
+	return &digitChallenge{IDValue: newID(), Digits: string(digits)}
+}
+
+func (c *digitChallenge) ID() string { return c.IDValue }
+
+func (c *digitChallenge) Render(w io.Writer, mimeType string) error {
+	switch mimeType {
+	case "image/png":
+		return renderImage(w, c.Digits, false)
+	default:
+		return ErrUnsupportedMimeType
+	}
+}
+
+func (c *digitChallenge) Verify(input string) bool {
+	return input == c.Digits
+}
+
+func (c *digitChallenge) kind() string { return "digit" }
@@ -0,0 +1,29 @@
+package challenge
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+)
+
+// newID returns a random hex string suitable for use as a Challenge ID.
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("challenge: failed to read random bytes: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+
+// secureIntn returns a cryptographically random integer in [0, n). Every
+// challenge kind uses this (not math/rand) to generate the secret a solver
+// must guess: a captcha service continuously reveals answers on successful
+// solves, which is exactly the kind of output stream math/rand's
+// non-cryptographic state can't be trusted to resist reconstruction from.
+func secureIntn(n int) int {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		panic("challenge: failed to read random bytes: " + err.Error())
+	}
+	return int(v.Int64())
+}
@@ -0,0 +1,107 @@
+package challenge
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"math/rand"
+)
+
+const (
+	imgScale    = 6 // pixels per glyph cell
+	imgPadding  = imgScale * 2
+	imgGlyphGap = imgScale
+)
+
+// errNoGlyph is returned by renderImage when text contains a rune the
+// bitmap font in font.go has no glyph for.
+var errNoGlyph = errors.New("challenge: text contains a character with no glyph")
+
+// renderImage draws text using the glyphs bitmap font and PNG-encodes the
+// result to w. When distort is true, a sine-wave warp and speckle noise are
+// applied on top, matching the "distorted-text" look textChallenge needs;
+// digitChallenge renders with distort set to false.
+func renderImage(w io.Writer, text string, distort bool) error {
+	if !glyphSet(text) {
+		return errNoGlyph
+	}
+
+	width := imgPadding*2 + len(text)*(glyphWidth*imgScale+imgGlyphGap)
+	height := imgPadding*2 + glyphHeight*imgScale
+
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+
+	x := imgPadding
+	for _, r := range text {
+		drawGlyph(img, glyphs[r], x, imgPadding)
+		x += glyphWidth*imgScale + imgGlyphGap
+	}
+
+	if distort {
+		img = warp(img)
+		speckle(img)
+	}
+
+	return png.Encode(w, img)
+}
+
+// drawGlyph paints a single glyph's "on" bits as black pixel blocks into img
+// at the given top-left offset.
+func drawGlyph(img *image.Gray, g [7]byte, ox, oy int) {
+	for row := 0; row < glyphHeight; row++ {
+		bits := g[row]
+		for col := 0; col < glyphWidth; col++ {
+			if bits&(1<<uint(glyphWidth-1-col)) == 0 {
+				continue
+			}
+			fillBlock(img, ox+col*imgScale, oy+row*imgScale, imgScale)
+		}
+	}
+}
+
+func fillBlock(img *image.Gray, x, y, size int) {
+	for dy := 0; dy < size; dy++ {
+		for dx := 0; dx < size; dx++ {
+			img.SetGray(x+dx, y+dy, color.Gray{Y: 0})
+		}
+	}
+}
+
+// warp applies a horizontal sine-wave displacement to make the text harder
+// for OCR to segment, the way dchest/captcha's image challenges do.
+func warp(src *image.Gray) *image.Gray {
+	b := src.Bounds()
+	dst := image.NewGray(b)
+	amplitude := float64(b.Dy()) / 12
+	period := float64(b.Dx()) / 4
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		offset := int(amplitude * math.Sin(2*math.Pi*float64(y)/period))
+		for x := b.Min.X; x < b.Max.X; x++ {
+			sx := x + offset
+			if sx < b.Min.X || sx >= b.Max.X {
+				dst.SetGray(x, y, color.Gray{Y: 0xFF})
+				continue
+			}
+			dst.SetGray(x, y, src.GrayAt(sx, y))
+		}
+	}
+	return dst
+}
+
+// speckle sprinkles random noise pixels over img to further frustrate
+// automated solvers.
+func speckle(img *image.Gray) {
+	b := img.Bounds()
+	n := b.Dx() * b.Dy() / 20
+	for i := 0; i < n; i++ {
+		x := b.Min.X + rand.Intn(b.Dx())
+		y := b.Min.Y + rand.Intn(b.Dy())
+		img.SetGray(x, y, color.Gray{Y: uint8(rand.Intn(0x80))})
+	}
+}
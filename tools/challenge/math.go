@@ -0,0 +1,64 @@
+package challenge
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var mathWords = [10]string{
+	"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+}
+
+func init() {
+	register("math", func() Challenge { return &mathChallenge{} })
+}
+
+// mathChallenge is a simple math-word problem, e.g. "what is three plus
+// five?". Its text/plain rendering needs no image or audio pipeline, which
+// makes it a cheap fallback when neither is available.
+type mathChallenge struct {
+	IDValue string `json:"id"`
+	A       int    `json:"a"`
+	B       int    `json:"b"`
+	Answer  int    `json:"answer"`
+	Prompt  string `json:"prompt"`
+}
+
+// NewMath returns a Challenge asking the user to add two random
+// single-digit numbers.
+func NewMath() Challenge {
+	a, b := secureIntn(10), secureIntn(10)
+	return &mathChallenge{
+		IDValue: newID(),
+		A:       a,
+		B:       b,
+		Answer:  a + b,
+		Prompt:  fmt.Sprintf("what is %s plus %s?", mathWords[a], mathWords[b]),
+	}
+}
+
+func (c *mathChallenge) ID() string { return c.IDValue }
+
+func (c *mathChallenge) Render(w io.Writer, mimeType string) error {
+	switch mimeType {
+	case "text/plain":
+		_, err := io.WriteString(w, c.Prompt)
+		return err
+	case "image/png":
+		return renderImage(w, strconv.Itoa(c.A)+"+"+strconv.Itoa(c.B)+"=?", false)
+	default:
+		return ErrUnsupportedMimeType
+	}
+}
+
+func (c *mathChallenge) Verify(input string) bool {
+	n, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil {
+		return false
+	}
+	return n == c.Answer
+}
+
+func (c *mathChallenge) kind() string { return "math" }
@@ -0,0 +1,44 @@
+package challenge
+
+import "io"
+
+const textAlphabet = "ACDEFGHJKLMNPQRSTUVWXYZ"
+
+func init() {
+	register("text", func() Challenge { return &textChallenge{} })
+}
+
+// textChallenge is a distorted-text image captcha: a random string of
+// letters rendered with a sine-wave warp and speckle noise, to
+// resist OCR better than a plain digitChallenge.
+type textChallenge struct {
+	IDValue string `json:"id"`
+	Text    string `json:"text"`
+}
+
+// NewText returns a Challenge made of n random letters (ambiguous
+// characters like I/O/0/1 are excluded).
+func NewText(n int) Challenge {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = textAlphabet[secureIntn(len(textAlphabet))]
+	}
+	return &textChallenge{IDValue: newID(), Text: string(b)}
+}
+
+func (c *textChallenge) ID() string { return c.IDValue }
+
+func (c *textChallenge) Render(w io.Writer, mimeType string) error {
+	switch mimeType {
+	case "image/png":
+		return renderImage(w, c.Text, true)
+	default:
+		return ErrUnsupportedMimeType
+	}
+}
+
+func (c *textChallenge) Verify(input string) bool {
+	return input == c.Text
+}
+
+func (c *textChallenge) kind() string { return "text" }
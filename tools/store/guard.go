@@ -0,0 +1,242 @@
+package store
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned by SetFor when clientKey has exceeded its
+// captcha issuance rate.
+var ErrRateLimited = errors.New("store: rate limit exceeded")
+
+// defaultBucketIdleTTL is how long a per-client rate-limit bucket may sit
+// unused before the janitor reclaims it, when GuardOpts.BucketIdleTTL isn't
+// set.
+const defaultBucketIdleTTL = 10 * time.Minute
+
+// guardScanInterval is how often the janitor goroutine sweeps consumed and
+// buckets for stale entries.
+const guardScanInterval = time.Minute
+
+// GuardOpts configures NewGuardedStore.
+type GuardOpts struct {
+	// RatePerMinute caps how many captchas a single client key (typically
+	// an IP) may generate per minute. Zero disables the rate limit.
+	RatePerMinute int
+
+	// ConsumedTTL is how long a consumed id is remembered so a replayed
+	// Get(id, true) returns empty even if the inner store hasn't expired
+	// (or GC'd) the id yet. Defaults to 5 minutes.
+	ConsumedTTL time.Duration
+
+	// BucketIdleTTL is how long a per-client key's rate-limit bucket may go
+	// untouched before the janitor reclaims it, so a client that varies its
+	// key (rotating IPs, spoofed forwarding headers, or just many distinct
+	// visitors) can't grow buckets without bound. Defaults to 10 minutes.
+	BucketIdleTTL time.Duration
+}
+
+// bucket is a simple token bucket: it holds at most its capacity, refills
+// at capacity tokens per minute, and is created lazily per client key.
+type bucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// GuardedStore wraps a Store with per-client rate limiting on generation
+// and single-use enforcement on verification, protecting registration-style
+// endpoints that a bare captcha doesn't: an attacker can't exhaust memory
+// by hammering the issue endpoint, and a captured id can't be replayed.
+type GuardedStore struct {
+	inner Store
+	opts  GuardOpts
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	// consumed holds ids that have been successfully verified, so a
+	// replay is rejected even before the inner store (or its GC) catches
+	// up. Values are the time the id should be forgotten.
+	consumed map[string]time.Time
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewGuardedStore wraps inner with per-client rate limiting and single-use
+// enforcement. The plain Set method, kept for Store compatibility, passes
+// straight to inner without rate limiting; Get enforces single-use. Callers
+// that want generation guarded should use SetFor. A janitor goroutine reclaims stale consumed entries and
+// rate-limit buckets; callers should call Close to stop it.
+func NewGuardedStore(inner Store, opts GuardOpts) *GuardedStore {
+	if opts.ConsumedTTL <= 0 {
+		opts.ConsumedTTL = 5 * time.Minute
+	}
+	if opts.BucketIdleTTL <= 0 {
+		opts.BucketIdleTTL = defaultBucketIdleTTL
+	}
+	s := &GuardedStore{
+		inner:    inner,
+		opts:     opts,
+		buckets:  make(map[string]*bucket),
+		consumed: make(map[string]time.Time),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go s.janitor()
+	return s
+}
+
+// janitor wakes up every guardScanInterval to reclaim expired consumed
+// entries and idle rate-limit buckets, so neither map grows without bound
+// for the lifetime of the process.
+func (s *GuardedStore) janitor() {
+	ticker := time.NewTicker(guardScanInterval)
+	defer ticker.Stop()
+	defer close(s.done)
+	for {
+		select {
+		case <-ticker.C:
+			s.evictStale()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *GuardedStore) evictStale() {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evictConsumedLocked(now)
+	for key, b := range s.buckets {
+		if now.Sub(b.lastRefill) > s.opts.BucketIdleTTL {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// Close stops the janitor goroutine, then closes inner if it implements
+// io.Closer, so composing GuardedStore with a store that has its own
+// background goroutine (memoryStore's janitor, for instance) shuts both
+// down with a single call.
+func (s *GuardedStore) Close() error {
+	s.closeOnce.Do(func() { close(s.stop) })
+	<-s.done
+	if closer, ok := s.inner.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Set stores value for id without rate limiting or single-use tracking, for
+// Store compatibility. Prefer SetFor so generation is guarded.
+func (s *GuardedStore) Set(id string, value string) error {
+	return s.inner.Set(id, value)
+}
+
+// Get looks up id, honoring the single-use negative cache: if id was
+// already consumed by an earlier Get(id, true) through this GuardedStore,
+// it returns "" without consulting inner at all. This is what makes
+// single-use actually hold against an inner store whose own clear-on-Get
+// isn't atomic, where two concurrent clear=true calls could otherwise both
+// see the value.
+func (s *GuardedStore) Get(id string, clear bool) (string, error) {
+	if !clear {
+		if s.Consumed(id) {
+			return "", nil
+		}
+		return s.inner.Get(id, false)
+	}
+	// Claim id before consulting inner, so that of two concurrent
+	// clear=true calls only the first one reaches inner.
+	s.mu.Lock()
+	s.evictConsumedLocked(time.Now())
+	_, seen := s.consumed[id]
+	if !seen {
+		s.consumed[id] = time.Now().Add(s.opts.ConsumedTTL)
+	}
+	s.mu.Unlock()
+	if seen {
+		return "", nil
+	}
+	value, err := s.inner.Get(id, true)
+	if err != nil || value == "" {
+		// Nothing was consumed; release the claim.
+		s.mu.Lock()
+		delete(s.consumed, id)
+		s.mu.Unlock()
+	}
+	if err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// SetFor stores value for id on behalf of clientKey (typically the
+// requesting IP), subject to the per-minute rate limit. It returns
+// ErrRateLimited if clientKey has issued too many captchas in the last
+// minute.
+func (s *GuardedStore) SetFor(id, value, clientKey string) error {
+	if s.opts.RatePerMinute > 0 && !s.allow(clientKey) {
+		return ErrRateLimited
+	}
+	return s.inner.Set(id, value)
+}
+
+// Consumed reports whether id has already been successfully verified (via
+// Get(id, true) through this GuardedStore), making it safe to reject
+// replays even if the inner store hasn't expired or GC'd the id yet.
+func (s *GuardedStore) Consumed(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evictConsumedLocked(time.Now())
+	_, ok := s.consumed[id]
+	return ok
+}
+
+func (s *GuardedStore) markConsumed(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.consumed[id] = time.Now().Add(s.opts.ConsumedTTL)
+}
+
+// evictConsumedLocked drops expired entries from consumed. Callers must
+// hold s.mu.
+func (s *GuardedStore) evictConsumedLocked(now time.Time) {
+	for id, expiry := range s.consumed {
+		if now.After(expiry) {
+			delete(s.consumed, id)
+		}
+	}
+}
+
+// allow consumes a token from clientKey's bucket, refilling it first based
+// on elapsed time, and reports whether the request is within the rate
+// limit.
+func (s *GuardedStore) allow(clientKey string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	b, ok := s.buckets[clientKey]
+	if !ok {
+		b = &bucket{tokens: float64(s.opts.RatePerMinute), lastRefill: now}
+		s.buckets[clientKey] = b
+	}
+
+	elapsed := now.Sub(b.lastRefill).Minutes()
+	b.tokens += elapsed * float64(s.opts.RatePerMinute)
+	if b.tokens > float64(s.opts.RatePerMinute) {
+		b.tokens = float64(s.opts.RatePerMinute)
+	}
+	b.lastRefill = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// stickyStore is a Store that never forgets a value, even when Get is
+// called with clear=true. It stands in for a hypothetical backend whose
+// clear-on-Get isn't atomic, so GuardedStore's own negative cache is what
+// single-use enforcement actually rests on.
+type stickyStore struct {
+	values map[string]string
+}
+
+func newStickyStore() *stickyStore {
+	return &stickyStore{values: make(map[string]string)}
+}
+
+func (s *stickyStore) Set(id string, value string) error {
+	s.values[id] = value
+	return nil
+}
+
+func (s *stickyStore) Get(id string, clear bool) (string, error) {
+	return s.values[id], nil
+}
+
+func TestGuardedStoreRateLimitsPerClient(t *testing.T) {
+	g := NewGuardedStore(NewMemoryStore(10, time.Minute, time.Minute), GuardOpts{RatePerMinute: 2})
+	defer g.Close()
+
+	if err := g.SetFor("id1", "1234", "1.2.3.4"); err != nil {
+		t.Fatalf("SetFor 1: %v", err)
+	}
+	if err := g.SetFor("id2", "1234", "1.2.3.4"); err != nil {
+		t.Fatalf("SetFor 2: %v", err)
+	}
+	if err := g.SetFor("id3", "1234", "1.2.3.4"); err != ErrRateLimited {
+		t.Fatalf("SetFor 3: got %v, want ErrRateLimited", err)
+	}
+	// A different client key has its own bucket.
+	if err := g.SetFor("id4", "1234", "5.6.7.8"); err != nil {
+		t.Fatalf("SetFor for other client: %v", err)
+	}
+}
+
+func TestGuardedStoreSingleUse(t *testing.T) {
+	g := NewGuardedStore(NewMemoryStore(10, time.Minute, time.Minute), GuardOpts{RatePerMinute: 10})
+	defer g.Close()
+
+	if err := g.SetFor("id", "1234", "1.2.3.4"); err != nil {
+		t.Fatalf("SetFor: %v", err)
+	}
+
+	value, err := g.Get("id", true)
+	if err != nil || value != "1234" {
+		t.Fatalf("first Get: value=%q err=%v, want 1234/nil", value, err)
+	}
+
+	if !g.Consumed("id") {
+		t.Fatal("expected id to be marked consumed after Get")
+	}
+
+	value, err = g.Get("id", true)
+	if err != nil || value != "" {
+		t.Fatalf("replayed Get: value=%q err=%v, want empty/nil", value, err)
+	}
+}
+
+// TestGuardedStoreSingleUseAgainstStickyInner verifies that single-use is
+// enforced by GuardedStore itself, not merely inherited from an inner store
+// that happens to delete on clear=true.
+func TestGuardedStoreSingleUseAgainstStickyInner(t *testing.T) {
+	g := NewGuardedStore(newStickyStore(), GuardOpts{RatePerMinute: 10})
+	defer g.Close()
+
+	if err := g.SetFor("id", "1234", "1.2.3.4"); err != nil {
+		t.Fatalf("SetFor: %v", err)
+	}
+
+	value, err := g.Get("id", true)
+	if err != nil || value != "1234" {
+		t.Fatalf("first Get: value=%q err=%v, want 1234/nil", value, err)
+	}
+
+	// The sticky inner store still has the value; only GuardedStore's
+	// negative cache stands between this and a replay succeeding.
+	value, err = g.Get("id", true)
+	if err != nil || value != "" {
+		t.Fatalf("replayed Get against sticky inner: value=%q err=%v, want empty/nil", value, err)
+	}
+}
+
+// TestGuardedStoreEvictsStaleBuckets verifies that a client's rate-limit
+// bucket is reclaimed once it's gone idle past BucketIdleTTL, so a stream
+// of distinct client keys can't grow buckets without bound.
+func TestGuardedStoreEvictsStaleBuckets(t *testing.T) {
+	g := NewGuardedStore(newStickyStore(), GuardOpts{RatePerMinute: 10, BucketIdleTTL: time.Millisecond})
+	defer g.Close()
+
+	if err := g.SetFor("id", "1234", "1.2.3.4"); err != nil {
+		t.Fatalf("SetFor: %v", err)
+	}
+
+	g.mu.Lock()
+	if len(g.buckets) != 1 {
+		g.mu.Unlock()
+		t.Fatalf("expected 1 bucket after SetFor, got %d", len(g.buckets))
+	}
+	g.buckets["1.2.3.4"].lastRefill = time.Now().Add(-time.Hour)
+	g.mu.Unlock()
+
+	g.evictStale()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.buckets) != 0 {
+		t.Fatalf("expected stale bucket to be evicted, got %d remaining", len(g.buckets))
+	}
+}
+
+// TestGuardedStoreCloseStopsInnerJanitor verifies that closing a
+// GuardedStore wrapping a memoryStore also stops the memoryStore's janitor
+// goroutine, so composing the two doesn't leak it.
+func TestGuardedStoreCloseStopsInnerJanitor(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	g := NewGuardedStore(NewMemoryStore(10, time.Second, 5*time.Millisecond), GuardOpts{RatePerMinute: 10})
+	// Let both janitors actually start running before we measure anything.
+	time.Sleep(20 * time.Millisecond)
+
+	if err := g.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if runtime.NumGoroutine() <= before {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("janitor goroutine leaked: had %d goroutines before, %d after Close", before, runtime.NumGoroutine())
+}
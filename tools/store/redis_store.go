@@ -0,0 +1,84 @@
+package store
+
+import (
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// RedisClient is the subset of *redis.Client (and *redis.ClusterClient) that
+// redisStore needs. Depending on an interface instead of the concrete client
+// type lets callers share a connection pool or cluster client across the
+// rest of their application.
+type RedisClient interface {
+	Set(key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Get(key string) *redis.StringCmd
+	Eval(script string, keys []string, args ...interface{}) *redis.Cmd
+}
+
+// redisStore is a Store backed by Redis. Unlike memoryStore it relies on
+// Redis' native key expiry (TTL) instead of an in-process sweep, which makes
+// it safe to share across multiple gorobbs instances.
+type redisStore struct {
+	client     RedisClient
+	keyPrefix  string
+	expiration time.Duration
+}
+
+// NewRedisStore returns a Store that persists captcha id/value pairs in
+// Redis under keyPrefix+id, expiring them after expiration. client may be a
+// *redis.Client or *redis.ClusterClient (or anything else implementing
+// RedisClient), and is expected to be shared with the rest of the
+// application rather than created per-store.
+func NewRedisStore(client RedisClient, keyPrefix string, expiration time.Duration) Store {
+	return &redisStore{
+		client:     client,
+		keyPrefix:  keyPrefix,
+		expiration: expiration,
+	}
+}
+
+func (s *redisStore) key(id string) string {
+	return s.keyPrefix + id
+}
+
+func (s *redisStore) Set(id string, value string) error {
+	return s.client.Set(s.key(id), value, s.expiration).Err()
+}
+
+// getDelScript atomically reads and deletes a key, so a Get(id, true) can't
+// race with a concurrent Get(id, true) the way a plain GET followed by a
+// separate DEL would: only one caller ever observes the value.
+const getDelScript = `
+local v = redis.call("GET", KEYS[1])
+if v then
+	redis.call("DEL", KEYS[1])
+end
+return v
+`
+
+func (s *redisStore) Get(id string, clear bool) (value string, err error) {
+	if !clear {
+		value, err = s.client.Get(s.key(id)).Result()
+		if err == redis.Nil {
+			return "", nil
+		}
+		return value, err
+	}
+
+	res, err := s.client.Eval(getDelScript, []string{s.key(id)}).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if res == nil {
+		return "", nil
+	}
+	value, ok := res.(string)
+	if !ok {
+		return "", nil
+	}
+	return value, nil
+}
@@ -6,6 +6,29 @@ import (
 	"time"
 )
 
+// Store is the interface for captcha id/value storage backends. It must be
+// safe for concurrent use.
+//
+// Set and Get return an error so that backends with a failure mode (network
+// stores such as Redis, for example) can report it to the caller instead of
+// silently losing the captcha. Backends that cannot fail, such as
+// memoryStore, always return a nil error.
+//
+// A Store that runs background work (memoryStore's janitor goroutine, for
+// instance) should additionally implement io.Closer so callers that want a
+// clean shutdown can type-assert for it; it is not part of this interface
+// because most backends don't need it.
+type Store interface {
+	// Set sets the value for the given id, overwriting any previous value.
+	Set(id string, value string) error
+
+	// Get returns the value for the given id. If clear is true, deletes the
+	// captcha from the store so it can't be re-verified. A returned value of
+	// "" with a nil error means the id was not found (already expired,
+	// already consumed, or never issued).
+	Get(id string, clear bool) (value string, err error)
+}
+
 // expValue stores timestamp and id of captchas. It is used in the list inside
 // memoryStore for indexing generated captchas by timestamp to enable garbage
 // collection of expired captchas.
@@ -14,43 +37,84 @@ type idByTimeValue struct {
 	id        string
 }
 
+// defaultScanInterval is how often the janitor goroutine wakes up to look
+// for expired captchas when NewMemoryStore is given scanInterval <= 0.
+const defaultScanInterval = 60 * time.Second
+
 // memoryStore is an internal store for captcha ids and their values.
 type memoryStore struct {
 	sync.RWMutex
 	digitsById map[string]string
 	idByTime   *list.List
-	// Number of items stored since last collection.
-	numStored int
-	// Number of saved items that triggers collection.
-	collectNum int
 	// Expiration time of captchas.
 	expiration time.Duration
+	// How often the janitor goroutine scans idByTime for expired entries.
+	scanInterval time.Duration
+	stop         chan struct{}
+	done         chan struct{}
+	closeOnce    sync.Once
 }
 
 // NewMemoryStore returns a new standard memory store for captchas with the
-// given collection threshold and expiration time (duration). The returned
-// store must be registered with SetCustomStore to replace the default one.
-func NewMemoryStore(collectNum int, expiration time.Duration) Store {
+// given expiration time (duration), and starts a janitor goroutine that
+// reclaims expired captchas every scanInterval (a scanInterval <= 0 means
+// defaultScanInterval, clamped down to expiration if that's smaller so
+// short-lived captchas don't outlive several scans before being collected).
+// collectNum sizes the initial map, as a hint of how many captchas are
+// expected to be live at once; it no longer triggers collection directly.
+// Callers that want to stop the janitor should call Close.
+func NewMemoryStore(collectNum int, expiration, scanInterval time.Duration) Store {
+	if scanInterval <= 0 {
+		scanInterval = defaultScanInterval
+	}
+	if expiration < scanInterval {
+		scanInterval = expiration
+	}
 	s := new(memoryStore)
-	s.digitsById = make(map[string]string)
+	s.digitsById = make(map[string]string, collectNum)
 	s.idByTime = list.New()
-	s.collectNum = collectNum
 	s.expiration = expiration
+	s.scanInterval = scanInterval
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.janitor()
 	return s
 }
 
-func (s *memoryStore) Set(id string, value string) {
+// janitor wakes up every scanInterval and reclaims expired captchas, so
+// memory is reclaimed even on an idle server instead of only as a
+// side-effect of Set.
+func (s *memoryStore) janitor() {
+	ticker := time.NewTicker(s.scanInterval)
+	defer ticker.Stop()
+	defer close(s.done)
+	for {
+		select {
+		case <-ticker.C:
+			s.collect()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Close stops the janitor goroutine. It always returns a nil error; the
+// return type is only there to satisfy io.Closer.
+func (s *memoryStore) Close() error {
+	s.closeOnce.Do(func() { close(s.stop) })
+	<-s.done
+	return nil
+}
+
+func (s *memoryStore) Set(id string, value string) error {
 	s.Lock()
 	s.digitsById[id] = value
 	s.idByTime.PushBack(idByTimeValue{time.Now(), id})
-	s.numStored++
 	s.Unlock()
-	if s.numStored > s.collectNum {
-		go s.collect()
-	}
+	return nil
 }
 
-func (s *memoryStore) Get(id string, clear bool) (value string) {
+func (s *memoryStore) Get(id string, clear bool) (value string, err error) {
 	if !clear {
 		// When we don't need to clear captcha, acquire read lock.
 		s.RLock()
@@ -61,14 +125,17 @@ func (s *memoryStore) Get(id string, clear bool) (value string) {
 	}
 	value, ok := s.digitsById[id]
 	if !ok {
-		return
+		return "", nil
 	}
 	if clear {
 		delete(s.digitsById, id)
 	}
-	return
+	return value, nil
 }
 
+// collect removes every expired entry from the front of idByTime. Since
+// idByTime is ordered by insertion time, it stops at the first entry that
+// hasn't expired yet: nothing after it can have expired either.
 func (s *memoryStore) collect() {
 	now := time.Now()
 	s.Lock()
@@ -89,7 +156,6 @@ func (s *memoryStore) collectOne(e *list.Element, specifyTime time.Time) *list.E
 		delete(s.digitsById, ev.id)
 		next := e.Next()
 		s.idByTime.Remove(e)
-		s.numStored--
 		return next
 	}
 	return nil
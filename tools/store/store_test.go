@@ -0,0 +1,54 @@
+package store
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// TestMemoryStoreJanitorExpiresWithoutSet verifies that captchas are
+// reclaimed by the janitor goroutine alone, with no Set calls to trigger
+// collection.
+func TestMemoryStoreJanitorExpiresWithoutSet(t *testing.T) {
+	s := NewMemoryStore(10, 20*time.Millisecond, 10*time.Millisecond).(*memoryStore)
+	defer s.Close()
+
+	if err := s.Set("id", "1234"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		s.RLock()
+		_, present := s.digitsById["id"]
+		s.RUnlock()
+		if !present {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("captcha was not collected by the janitor within the deadline")
+}
+
+// TestMemoryStoreCloseStopsJanitor verifies that Close stops the janitor
+// goroutine instead of leaking it.
+func TestMemoryStoreCloseStopsJanitor(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	s := NewMemoryStore(10, time.Second, 5*time.Millisecond)
+	// Let the janitor actually start running before we measure anything.
+	time.Sleep(20 * time.Millisecond)
+
+	if err := s.(*memoryStore).Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if runtime.NumGoroutine() <= before {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("janitor goroutine leaked: had %d goroutines before, %d after Close", before, runtime.NumGoroutine())
+}